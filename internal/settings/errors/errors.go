@@ -0,0 +1,21 @@
+// Package errors holds sentinel errors shared across DNS provider
+// implementations in internal/settings/providers.
+package errors
+
+import "errors"
+
+// Aliyun record validation errors.
+var (
+	// ErrEmptyHost is returned when a configured record has no host set.
+	ErrEmptyHost = errors.New("host is empty")
+)
+
+// Aliyun RAM STS credential validation errors.
+var (
+	// ErrEmptyRoleARN is returned when role_session_name is set without role_arn.
+	ErrEmptyRoleARN = errors.New("role ARN is empty")
+	// ErrEmptyRoleSessionName is returned when role_arn is set without role_session_name.
+	ErrEmptyRoleSessionName = errors.New("role session name is empty")
+	// ErrConflictingCredentials is returned when both role_arn and security_token are set.
+	ErrConflictingCredentials = errors.New("role_arn and security_token cannot both be set")
+)
@@ -0,0 +1,148 @@
+// Package internal implements a small typed client around the Aliyun
+// alidns SDK, used by the aliyun provider.
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/sdk/requests"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/sts"
+)
+
+// sdkAPI is the subset of *alidns.Client methods the provider needs. It is
+// satisfied by the real SDK client, and faked in tests since the vendor SDK
+// does not expose a plain HTTP transport to point at a httptest.Server.
+type sdkAPI interface {
+	DescribeDomainRecords(request *alidns.DescribeDomainRecordsRequest) (*alidns.DescribeDomainRecordsResponse, error)
+	AddDomainRecord(request *alidns.AddDomainRecordRequest) (*alidns.AddDomainRecordResponse, error)
+	UpdateDomainRecord(request *alidns.UpdateDomainRecordRequest) (*alidns.UpdateDomainRecordResponse, error)
+}
+
+// Client wraps the parts of the alidns SDK client used by the provider.
+type Client struct {
+	sdkClient sdkAPI
+}
+
+func NewClient(region, accessKeyID, accessSecret string) (*Client, error) {
+	sdkClient, err := alidns.NewClientWithAccessKey(region, accessKeyID, accessSecret)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{sdkClient: sdkClient}, nil
+}
+
+// NewClientWithStsToken builds a client authenticated with short-lived RAM
+// STS credentials, either provided directly or obtained from AssumeRole.
+func NewClientWithStsToken(region, accessKeyID, accessSecret, securityToken string) (*Client, error) {
+	sdkClient, err := alidns.NewClientWithStsToken(region, accessKeyID, accessSecret, securityToken)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{sdkClient: sdkClient}, nil
+}
+
+// AssumeRole calls RAM STS AssumeRole using the given long-lived credentials
+// and returns the resulting temporary credentials.
+func AssumeRole(region, accessKeyID, accessSecret, roleARN, roleSessionName string) (Credentials, error) {
+	stsClient, err := sts.NewClientWithAccessKey(region, accessKeyID, accessSecret)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	request := sts.CreateAssumeRoleRequest()
+	request.Scheme = "https"
+	request.RoleArn = roleARN
+	request.RoleSessionName = roleSessionName
+
+	response, err := stsClient.AssumeRole(request)
+	if err != nil {
+		return Credentials{}, err
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, response.Credentials.Expiration)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("parsing STS credentials expiration: %w", err)
+	}
+
+	return Credentials{
+		AccessKeyID:   response.Credentials.AccessKeyId,
+		AccessSecret:  response.Credentials.AccessKeySecret,
+		SecurityToken: response.Credentials.SecurityToken,
+		ExpiresAt:     expiresAt,
+	}, nil
+}
+
+// listRecordsPageSize is the page size requested from DescribeDomainRecords;
+// the API defaults to ~20 records per page, which silently truncates
+// domains with more records than that if left unset.
+const listRecordsPageSize = 100
+
+// ListRecords returns every DNS record configured for domain, paging through
+// DescribeDomainRecords until all of them have been collected.
+func (c *Client) ListRecords(_ context.Context, domain string) (records []Record, err error) {
+	for pageNumber := 1; ; pageNumber++ {
+		request := alidns.CreateDescribeDomainRecordsRequest()
+		request.Scheme = "https"
+		request.DomainName = domain
+		request.PageSize = requests.NewInteger(listRecordsPageSize)
+		request.PageNumber = requests.NewInteger(pageNumber)
+
+		response, err := c.sdkClient.DescribeDomainRecords(request)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response.DomainRecords.Record) == 0 {
+			break
+		}
+
+		for _, record := range response.DomainRecords.Record {
+			records = append(records, Record{
+				ID:    record.RecordId,
+				RR:    record.RR,
+				Type:  record.Type,
+				Value: record.Value,
+			})
+		}
+
+		if len(records) >= response.TotalCount {
+			break
+		}
+	}
+	return records, nil
+}
+
+// CreateRecord creates a new record for domain and returns its record ID.
+func (c *Client) CreateRecord(_ context.Context, domain, rr, recordType, value string, ttl uint32) (
+	recordID string, err error) {
+	request := alidns.CreateAddDomainRecordRequest()
+	request.Scheme = "https"
+	request.DomainName = domain
+	request.RR = rr
+	request.Type = recordType
+	request.Value = value
+	request.TTL = requests.NewInteger(int(ttl))
+
+	response, err := c.sdkClient.AddDomainRecord(request)
+	if err != nil {
+		return "", err
+	}
+	return response.RecordId, nil
+}
+
+// UpdateRecord sets the value, type and TTL of the record identified by recordID.
+func (c *Client) UpdateRecord(_ context.Context, recordID, rr, recordType, value string, ttl uint32) (err error) {
+	request := alidns.CreateUpdateDomainRecordRequest()
+	request.Scheme = "https"
+	request.RecordId = recordID
+	request.RR = rr
+	request.Type = recordType
+	request.Value = value
+	request.TTL = requests.NewInteger(int(ttl))
+
+	_, err = c.sdkClient.UpdateDomainRecord(request)
+	return err
+}
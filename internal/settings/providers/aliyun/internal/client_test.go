@@ -0,0 +1,190 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSDK implements sdkAPI for tests, since the vendor SDK does not expose
+// a plain HTTP transport that can be pointed at a httptest.Server.
+type fakeSDK struct {
+	describeResponse  *alidns.DescribeDomainRecordsResponse
+	describeResponses []*alidns.DescribeDomainRecordsResponse // one per page, in order
+	describeErr       error
+	addResponse       *alidns.AddDomainRecordResponse
+	addErr            error
+	updateErr         error
+
+	describeCalls int
+}
+
+func (f *fakeSDK) DescribeDomainRecords(_ *alidns.DescribeDomainRecordsRequest) (
+	*alidns.DescribeDomainRecordsResponse, error) {
+	if f.describeErr != nil {
+		return nil, f.describeErr
+	}
+	if f.describeResponses != nil {
+		response := f.describeResponses[f.describeCalls]
+		f.describeCalls++
+		return response, nil
+	}
+	return f.describeResponse, nil
+}
+
+func (f *fakeSDK) AddDomainRecord(_ *alidns.AddDomainRecordRequest) (*alidns.AddDomainRecordResponse, error) {
+	return f.addResponse, f.addErr
+}
+
+func (f *fakeSDK) UpdateDomainRecord(_ *alidns.UpdateDomainRecordRequest) (
+	*alidns.UpdateDomainRecordResponse, error) {
+	return &alidns.UpdateDomainRecordResponse{}, f.updateErr
+}
+
+func Test_Client_ListRecords(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		sdk             *fakeSDK
+		expectedRecords []Record
+		expectedError   string
+	}{
+		"success": {
+			sdk: &fakeSDK{
+				describeResponse: &alidns.DescribeDomainRecordsResponse{
+					DomainRecords: alidns.DomainRecordsInDescribeDomainRecords{
+						Record: []alidns.RecordInDescribeDomainRecords{
+							{RecordId: "1", RR: "home", Type: "A", Value: "1.2.3.4"},
+						},
+					},
+				},
+			},
+			expectedRecords: []Record{
+				{ID: "1", RR: "home", Type: "A", Value: "1.2.3.4"},
+			},
+		},
+		"sdk error": {
+			sdk:           &fakeSDK{describeErr: errors.New("boom")},
+			expectedError: "boom",
+		},
+		"paginates across multiple pages": {
+			sdk: &fakeSDK{
+				describeResponses: []*alidns.DescribeDomainRecordsResponse{
+					{
+						TotalCount: 2,
+						DomainRecords: alidns.DomainRecordsInDescribeDomainRecords{
+							Record: []alidns.RecordInDescribeDomainRecords{
+								{RecordId: "1", RR: "home", Type: "A", Value: "1.2.3.4"},
+							},
+						},
+					},
+					{
+						TotalCount: 2,
+						DomainRecords: alidns.DomainRecordsInDescribeDomainRecords{
+							Record: []alidns.RecordInDescribeDomainRecords{
+								{RecordId: "2", RR: "vpn", Type: "A", Value: "5.6.7.8"},
+							},
+						},
+					},
+				},
+			},
+			expectedRecords: []Record{
+				{ID: "1", RR: "home", Type: "A", Value: "1.2.3.4"},
+				{ID: "2", RR: "vpn", Type: "A", Value: "5.6.7.8"},
+			},
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{sdkClient: testCase.sdk}
+			records, err := client.ListRecords(context.Background(), "example.com")
+
+			if testCase.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedRecords, records)
+		})
+	}
+}
+
+func Test_Client_CreateRecord(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		sdk              *fakeSDK
+		expectedRecordID string
+		expectedError    string
+	}{
+		"success": {
+			sdk:              &fakeSDK{addResponse: &alidns.AddDomainRecordResponse{RecordId: "42"}},
+			expectedRecordID: "42",
+		},
+		"sdk error": {
+			sdk:           &fakeSDK{addErr: errors.New("boom")},
+			expectedError: "boom",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{sdkClient: testCase.sdk}
+			recordID, err := client.CreateRecord(context.Background(), "example.com", "home", "A", "1.2.3.4", 600)
+
+			if testCase.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedRecordID, recordID)
+		})
+	}
+}
+
+func Test_Client_UpdateRecord(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		sdk           *fakeSDK
+		expectedError string
+	}{
+		"success": {
+			sdk: &fakeSDK{},
+		},
+		"sdk error": {
+			sdk:           &fakeSDK{updateErr: errors.New("boom")},
+			expectedError: "boom",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			client := &Client{sdkClient: testCase.sdk}
+			err := client.UpdateRecord(context.Background(), "1", "home", "A", "1.2.3.4", 600)
+
+			if testCase.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,19 @@
+package internal
+
+import "time"
+
+// Record is a DNS record as returned by the Aliyun DescribeDomainRecords API.
+type Record struct {
+	ID    string
+	RR    string
+	Type  string
+	Value string
+}
+
+// Credentials are short-lived RAM STS credentials assumed from a role.
+type Credentials struct {
+	AccessKeyID   string
+	AccessSecret  string
+	SecurityToken string
+	ExpiresAt     time.Time
+}
@@ -7,45 +7,103 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/aliyun/alibaba-cloud-sdk-go/services/alidns"
 	"github.com/qdm12/ddns-updater/internal/models"
 	"github.com/qdm12/ddns-updater/internal/settings/constants"
 	"github.com/qdm12/ddns-updater/internal/settings/errors"
+	"github.com/qdm12/ddns-updater/internal/settings/providers/aliyun/internal"
 	"github.com/qdm12/ddns-updater/internal/settings/utils"
 	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
 )
 
+// stsExpiryMargin is subtracted from the STS credentials expiry so that a
+// refresh happens slightly before they actually expire.
+const stsExpiryMargin = time.Minute
+
+// Record identifies a single (host, type) pair managed by the provider.
+// Type may be left empty, in which case it is derived from the IP version
+// being updated.
+type Record struct {
+	Host string `json:"host"`
+	Type string `json:"type"`
+}
+
 type Provider struct {
-	domain       string
-	host         string
-	ipVersion    ipversion.IPVersion
-	accessKeyID  string
-	accessSecret string
-	region       string
+	domain          string
+	ipVersion       ipversion.IPVersion
+	accessKeyID     string
+	accessSecret    string
+	region          string
+	records         []Record
+	createIfMissing bool
+	ttl             uint32
+
+	// securityToken holds a user-provided STS security token, used as is
+	// alongside accessKeyID/accessSecret.
+	securityToken string
+	// roleARN and roleSessionName, when set, make the provider assume a RAM
+	// role via STS instead of using accessKeyID/accessSecret directly.
+	roleARN         string
+	roleSessionName string
+
+	credentialsMutex sync.Mutex
+	credentials      internal.Credentials
+
+	// assumeRole is internal.AssumeRole by default; overridden in tests.
+	assumeRole func(region, accessKeyID, accessSecret, roleARN, roleSessionName string) (internal.Credentials, error)
+}
+
+// dnsClient is the subset of *internal.Client used by Update, extracted so
+// that tests can exercise the orchestration logic below without making
+// real Aliyun API calls.
+type dnsClient interface {
+	ListRecords(ctx context.Context, domain string) (records []internal.Record, err error)
+	CreateRecord(ctx context.Context, domain, rr, recordType, value string, ttl uint32) (recordID string, err error)
+	UpdateRecord(ctx context.Context, recordID, rr, recordType, value string, ttl uint32) (err error)
 }
 
 func New(data json.RawMessage, domain, host string,
 	ipVersion ipversion.IPVersion) (p *Provider, err error) {
 	extraSettings := struct {
-		AccessKeyID  string `json:"access_key_id"`
-		AccessSecret string `json:"access_secret"`
-		Region       string `json:"region"`
+		AccessKeyID     string   `json:"access_key_id"`
+		AccessSecret    string   `json:"access_secret"`
+		Region          string   `json:"region"`
+		Records         []Record `json:"records"`
+		CreateIfMissing bool     `json:"create_if_missing"`
+		TTL             uint32   `json:"ttl"`
+		SecurityToken   string   `json:"security_token"`
+		RoleARN         string   `json:"role_arn"`
+		RoleSessionName string   `json:"role_session_name"`
 	}{}
 	if err := json.Unmarshal(data, &extraSettings); err != nil {
 		return nil, err
 	}
+	records := extraSettings.Records
+	if len(records) == 0 {
+		records = []Record{{Host: host}}
+	}
 	p = &Provider{
-		domain:       domain,
-		host:         host,
-		ipVersion:    ipVersion,
-		accessKeyID:  extraSettings.AccessKeyID,
-		accessSecret: extraSettings.AccessSecret,
-		region:       "cn-hangzhou",
+		domain:          domain,
+		ipVersion:       ipVersion,
+		accessKeyID:     extraSettings.AccessKeyID,
+		accessSecret:    extraSettings.AccessSecret,
+		region:          "cn-hangzhou",
+		records:         records,
+		createIfMissing: extraSettings.CreateIfMissing,
+		ttl:             600,
+		securityToken:   extraSettings.SecurityToken,
+		roleARN:         extraSettings.RoleARN,
+		roleSessionName: extraSettings.RoleSessionName,
+		assumeRole:      internal.AssumeRole,
 	}
 	if extraSettings.Region != "" {
 		p.region = extraSettings.Region
 	}
+	if extraSettings.TTL != 0 {
+		p.ttl = extraSettings.TTL
+	}
 	if err := p.isValid(); err != nil {
 		return nil, err
 	}
@@ -58,12 +116,23 @@ func (p *Provider) isValid() error {
 		return errors.ErrEmptyAccessKeyID
 	case p.accessSecret == "":
 		return errors.ErrEmptyAccessKeySecret
+	case p.roleARN == "" && p.roleSessionName != "":
+		return errors.ErrEmptyRoleARN
+	case p.roleARN != "" && p.roleSessionName == "":
+		return errors.ErrEmptyRoleSessionName
+	case p.roleARN != "" && p.securityToken != "":
+		return errors.ErrConflictingCredentials
+	}
+	for _, record := range p.records {
+		if record.Host == "" {
+			return errors.ErrEmptyHost
+		}
 	}
 	return nil
 }
 
 func (p *Provider) String() string {
-	return utils.ToString(p.domain, p.host, constants.Aliyun, p.ipVersion)
+	return utils.ToString(p.domain, p.hostsString(), constants.Aliyun, p.ipVersion)
 }
 
 func (p *Provider) Domain() string {
@@ -71,7 +140,7 @@ func (p *Provider) Domain() string {
 }
 
 func (p *Provider) Host() string {
-	return p.host
+	return p.hostsString()
 }
 
 func (p *Provider) IPVersion() ipversion.IPVersion {
@@ -82,58 +151,147 @@ func (p *Provider) Proxied() bool {
 	return false
 }
 
+func (p *Provider) hostsString() string {
+	hosts := make([]string, len(p.records))
+	for i, record := range p.records {
+		hosts[i] = record.Host
+	}
+	return strings.Join(hosts, ", ")
+}
+
+// BuildDomainName returns the fully qualified domain names of all the
+// records managed by this provider, comma separated.
 func (p *Provider) BuildDomainName() string {
-	return utils.BuildDomainName(p.host, p.domain)
+	domainNames := make([]string, len(p.records))
+	for i, record := range p.records {
+		domainNames[i] = utils.BuildDomainName(record.Host, p.domain)
+	}
+	return strings.Join(domainNames, ", ")
 }
 
 func (p *Provider) HTML() models.HTMLRow {
+	domainNames := strings.Split(p.BuildDomainName(), ", ")
+	links := make([]string, len(domainNames))
+	for i, domainName := range domainNames {
+		links[i] = fmt.Sprintf("<a href=\"http://%s\">%s</a>", domainName, domainName)
+	}
 	return models.HTMLRow{
-		Domain:    models.HTML(fmt.Sprintf("<a href=\"http://%s\">%s</a>", p.BuildDomainName(), p.BuildDomainName())),
-		Host:      models.HTML(p.Host()),
+		Domain:    models.HTML(strings.Join(links, ", ")),
+		Host:      models.HTML(p.hostsString()),
 		Provider:  "<a href=\"https://www.aliyun.com/\">Aliyun</a>",
 		IPVersion: models.HTML(p.ipVersion.String()),
 	}
 }
 
-func (p *Provider) Update(ctx context.Context, _ *http.Client, ip net.IP) (newIP net.IP, err error) {
-	recordType := constants.A
-	if ip.To4() == nil {
-		recordType = constants.AAAA
+// recordUpdateErrors collects per-record update failures, keyed by
+// "host/type" so that two records sharing a host (e.g. A and AAAA) don't
+// overwrite each other's errors. It exists so that a failure updating one
+// record does not prevent the others from being updated.
+type recordUpdateErrors map[string]error
+
+func (e recordUpdateErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for recordKey, err := range e {
+		messages = append(messages, fmt.Sprintf("%s: %s", recordKey, err))
+	}
+	return strings.Join(messages, "; ")
+}
+
+// client builds the alidns client to use for this tick, assuming or
+// refreshing a RAM role's temporary credentials first if one is configured.
+func (p *Provider) client() (dnsClient, error) {
+	switch {
+	case p.roleARN != "":
+		credentials, err := p.assumedRoleCredentials()
+		if err != nil {
+			return nil, err
+		}
+		return internal.NewClientWithStsToken(p.region, credentials.AccessKeyID, credentials.AccessSecret,
+			credentials.SecurityToken)
+	case p.securityToken != "":
+		return internal.NewClientWithStsToken(p.region, p.accessKeyID, p.accessSecret, p.securityToken)
+	default:
+		return internal.NewClient(p.region, p.accessKeyID, p.accessSecret)
+	}
+}
+
+// assumedRoleCredentials returns cached RAM STS credentials, re-assuming the
+// role if they are missing or about to expire.
+func (p *Provider) assumedRoleCredentials() (internal.Credentials, error) {
+	p.credentialsMutex.Lock()
+	defer p.credentialsMutex.Unlock()
+
+	if p.credentials.SecurityToken != "" && time.Now().Before(p.credentials.ExpiresAt.Add(-stsExpiryMargin)) {
+		return p.credentials, nil
 	}
 
-	client, err := alidns.NewClientWithAccessKey(p.region, p.accessKeyID, p.accessSecret)
+	credentials, err := p.assumeRole(p.region, p.accessKeyID, p.accessSecret, p.roleARN, p.roleSessionName)
+	if err != nil {
+		return internal.Credentials{}, err
+	}
+	p.credentials = credentials
+	return credentials, nil
+}
+
+func (p *Provider) Update(ctx context.Context, _ *http.Client, ip net.IP) (newIP net.IP, err error) {
+	client, err := p.client()
 	if err != nil {
 		return nil, err
 	}
+	return p.updateRecords(ctx, client, ip)
+}
 
-	listRequest := alidns.CreateDescribeDomainRecordsRequest()
-	listRequest.Scheme = "https"
+// updateRecords contains the multi-record orchestration logic: listing the
+// domain's existing records, then creating, updating or skipping each
+// configured record as needed. It is split out from Update so it can be
+// exercised with a fake dnsClient in tests.
+func (p *Provider) updateRecords(ctx context.Context, client dnsClient, ip net.IP) (newIP net.IP, err error) {
+	defaultRecordType := constants.A
+	if ip.To4() == nil {
+		defaultRecordType = constants.AAAA
+	}
 
-	listRequest.DomainName = p.domain
-	listRequest.RRKeyWord = p.host
-	resp, err := client.DescribeDomainRecords(listRequest)
+	existingRecords, err := client.ListRecords(ctx, p.domain)
 	if err != nil {
 		return nil, err
 	}
-	recordID := ""
-	for _, record := range resp.DomainRecords.Record {
-		if strings.EqualFold(record.RR, p.host) {
-			recordID = record.RecordId
-			break
+
+	updateErrors := make(recordUpdateErrors)
+	for _, record := range p.records {
+		recordType := defaultRecordType
+		if record.Type != "" {
+			recordType = record.Type
 		}
-	}
-	if recordID == "" {
-		return nil, errors.ErrRecordNotFound
-	}
+		recordKey := record.Host + "/" + recordType
 
-	request := alidns.CreateUpdateDomainRecordRequest()
-	request.Scheme = "https"
+		existing, found := findRecord(existingRecords, record.Host, recordType)
+		switch {
+		case !found && !p.createIfMissing:
+			updateErrors[recordKey] = errors.ErrRecordNotFound
+		case !found:
+			if _, err := client.CreateRecord(ctx, p.domain, record.Host, recordType, ip.String(), p.ttl); err != nil {
+				updateErrors[recordKey] = err
+			}
+		case existing.Value == ip.String():
+			// already up to date
+		default:
+			if err := client.UpdateRecord(ctx, existing.ID, record.Host, recordType, ip.String(), p.ttl); err != nil {
+				updateErrors[recordKey] = err
+			}
+		}
+	}
 
-	request.Value = ip.String()
-	request.Type = recordType
-	request.RR = p.host
-	request.RecordId = recordID
+	if len(updateErrors) > 0 {
+		return nil, updateErrors
+	}
+	return ip, nil
+}
 
-	_, err = client.UpdateDomainRecord(request)
-	return ip, err
+func findRecord(records []internal.Record, host, recordType string) (record internal.Record, found bool) {
+	for _, record := range records {
+		if strings.EqualFold(record.RR, host) && strings.EqualFold(record.Type, recordType) {
+			return record, true
+		}
+	}
+	return internal.Record{}, false
 }
@@ -0,0 +1,230 @@
+package aliyun
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/qdm12/ddns-updater/internal/settings/providers/aliyun/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDNSClient implements dnsClient for tests.
+type fakeDNSClient struct {
+	listRecords []internal.Record
+	listErr     error
+	createErr   error
+	updateErr   error
+	createCalls []string // record host/type created
+	updateCalls []string // record host/type updated
+}
+
+func (f *fakeDNSClient) ListRecords(_ context.Context, _ string) ([]internal.Record, error) {
+	return f.listRecords, f.listErr
+}
+
+func (f *fakeDNSClient) CreateRecord(_ context.Context, _, rr, recordType, _ string, _ uint32) (string, error) {
+	f.createCalls = append(f.createCalls, rr+"/"+recordType)
+	return "new-id", f.createErr
+}
+
+func (f *fakeDNSClient) UpdateRecord(_ context.Context, _, rr, recordType, _ string, _ uint32) error {
+	f.updateCalls = append(f.updateCalls, rr+"/"+recordType)
+	return f.updateErr
+}
+
+func Test_Provider_updateRecords(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		provider        *Provider
+		client          *fakeDNSClient
+		expectedIP      net.IP
+		expectedErr     bool
+		expectedCreates []string
+		expectedUpdates []string
+	}{
+		"updates a stale record": {
+			provider: &Provider{
+				domain:  "example.com",
+				records: []Record{{Host: "home"}},
+			},
+			client: &fakeDNSClient{
+				listRecords: []internal.Record{{ID: "1", RR: "home", Type: "A", Value: "1.1.1.1"}},
+			},
+			expectedIP:      net.ParseIP("1.2.3.4"),
+			expectedUpdates: []string{"home/A"},
+		},
+		"skips an up to date record": {
+			provider: &Provider{
+				domain:  "example.com",
+				records: []Record{{Host: "home"}},
+			},
+			client: &fakeDNSClient{
+				listRecords: []internal.Record{{ID: "1", RR: "home", Type: "A", Value: "1.2.3.4"}},
+			},
+			expectedIP: net.ParseIP("1.2.3.4"),
+		},
+		"errors when a record is missing and create_if_missing is false": {
+			provider: &Provider{
+				domain:  "example.com",
+				records: []Record{{Host: "home"}},
+			},
+			client:      &fakeDNSClient{},
+			expectedErr: true,
+		},
+		"creates a missing record when create_if_missing is true": {
+			provider: &Provider{
+				domain:          "example.com",
+				records:         []Record{{Host: "home"}},
+				createIfMissing: true,
+			},
+			client:          &fakeDNSClient{},
+			expectedIP:      net.ParseIP("1.2.3.4"),
+			expectedCreates: []string{"home/A"},
+		},
+		"one failing record does not block the others": {
+			provider: &Provider{
+				domain: "example.com",
+				records: []Record{
+					{Host: "home"},
+					{Host: "vpn"},
+				},
+			},
+			client: &fakeDNSClient{
+				listRecords: []internal.Record{
+					{ID: "1", RR: "home", Type: "A", Value: "1.1.1.1"},
+					{ID: "2", RR: "vpn", Type: "A", Value: "1.1.1.1"},
+				},
+				updateErr: errors.New("boom"),
+			},
+			expectedErr:     true,
+			expectedUpdates: []string{"home/A", "vpn/A"},
+		},
+		"same host with different types are tracked independently": {
+			provider: &Provider{
+				domain: "example.com",
+				records: []Record{
+					{Host: "home", Type: "A"},
+					{Host: "home", Type: "AAAA"},
+				},
+				createIfMissing: false,
+			},
+			client:      &fakeDNSClient{}, // neither record exists
+			expectedErr: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			newIP, err := testCase.provider.updateRecords(context.Background(), testCase.client, net.ParseIP("1.2.3.4"))
+
+			if testCase.expectedErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, testCase.expectedIP, newIP)
+			}
+			assert.Equal(t, testCase.expectedCreates, testCase.client.createCalls)
+			assert.Equal(t, testCase.expectedUpdates, testCase.client.updateCalls)
+		})
+	}
+}
+
+func Test_Provider_updateRecords_sameHostBothTypesFail(t *testing.T) {
+	t.Parallel()
+
+	provider := &Provider{
+		domain: "example.com",
+		records: []Record{
+			{Host: "home", Type: "A"},
+			{Host: "home", Type: "AAAA"},
+		},
+	}
+	client := &fakeDNSClient{} // no existing records, create_if_missing is false
+
+	_, err := provider.updateRecords(context.Background(), client, net.ParseIP("1.2.3.4"))
+
+	require.Error(t, err)
+	updateErrs, ok := err.(recordUpdateErrors)
+	require.True(t, ok)
+	assert.Len(t, updateErrs, 2)
+	assert.Contains(t, updateErrs, "home/A")
+	assert.Contains(t, updateErrs, "home/AAAA")
+}
+
+func Test_Provider_assumedRoleCredentials(t *testing.T) {
+	t.Parallel()
+
+	t.Run("assumes the role and caches the result", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		provider := &Provider{
+			roleARN:         "role-arn",
+			roleSessionName: "session",
+			assumeRole: func(_, _, _, _, _ string) (internal.Credentials, error) {
+				calls++
+				return internal.Credentials{
+					AccessKeyID:   "key",
+					SecurityToken: "token",
+					ExpiresAt:     time.Now().Add(time.Hour),
+				}, nil
+			},
+		}
+
+		credentials, err := provider.assumedRoleCredentials()
+		require.NoError(t, err)
+		assert.Equal(t, "key", credentials.AccessKeyID)
+		assert.Equal(t, 1, calls)
+
+		// A second call before expiry should reuse the cached credentials.
+		_, err = provider.assumedRoleCredentials()
+		require.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("re-assumes the role once cached credentials are near expiry", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		provider := &Provider{
+			roleARN:         "role-arn",
+			roleSessionName: "session",
+			credentials: internal.Credentials{
+				SecurityToken: "stale-token",
+				ExpiresAt:     time.Now(),
+			},
+			assumeRole: func(_, _, _, _, _ string) (internal.Credentials, error) {
+				calls++
+				return internal.Credentials{SecurityToken: "fresh-token", ExpiresAt: time.Now().Add(time.Hour)}, nil
+			},
+		}
+
+		credentials, err := provider.assumedRoleCredentials()
+		require.NoError(t, err)
+		assert.Equal(t, "fresh-token", credentials.SecurityToken)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("propagates the AssumeRole error", func(t *testing.T) {
+		t.Parallel()
+
+		provider := &Provider{
+			roleARN:         "role-arn",
+			roleSessionName: "session",
+			assumeRole: func(_, _, _, _, _ string) (internal.Credentials, error) {
+				return internal.Credentials{}, errors.New("boom")
+			},
+		}
+
+		_, err := provider.assumedRoleCredentials()
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,95 @@
+// Package internal implements a small typed client for the DonDominio
+// simple-API, used by the dondominio provider.
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/qdm12/ddns-updater/internal/settings/errors"
+	"github.com/qdm12/ddns-updater/internal/settings/headers"
+	"github.com/qdm12/ddns-updater/internal/settings/utils"
+)
+
+const defaultBaseURL = "https://simple-api.dondominio.net"
+
+// Client is a thin wrapper around the DonDominio simple-API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	username   string
+	password   string
+}
+
+func NewClient(httpClient *http.Client, username, password string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    defaultBaseURL,
+		username:   username,
+		password:   password,
+	}
+}
+
+// UpdateDomainIP calls the simple-API's dynamic update endpoint for domain,
+// setting the A or AAAA record for name to ip, and returns the glue record
+// matching name from the response.
+func (c *Client) UpdateDomainIP(ctx context.Context, domain, name string, ip net.IP) (record GlueRecord, err error) {
+	values := url.Values{}
+	values.Set("apiuser", c.username)
+	values.Set("apipasswd", c.password)
+	values.Set("domain", domain)
+	values.Set("name", name)
+	isIPv4 := ip.To4() != nil
+	if isIPv4 {
+		values.Set("ipv4", ip.String())
+	} else {
+		values.Set("ipv6", ip.String())
+	}
+	buffer := strings.NewReader(values.Encode())
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL, buffer)
+	if err != nil {
+		return GlueRecord{}, err
+	}
+	setHeaders(request)
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return GlueRecord{}, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return GlueRecord{}, fmt.Errorf("%w: %d: %s",
+			errors.ErrBadHTTPStatus, response.StatusCode, utils.BodyToSingleLine(response.Body))
+	}
+
+	var responseData updateDomainIPResponse
+	decoder := json.NewDecoder(response.Body)
+	if err := decoder.Decode(&responseData); err != nil {
+		return GlueRecord{}, fmt.Errorf("%w: %s", errors.ErrUnmarshalResponse, err)
+	}
+
+	if !responseData.Success {
+		return GlueRecord{}, fmt.Errorf("%w: %s (error code %d)",
+			errors.ErrUnsuccessfulResponse, responseData.ErrorCodeMessage, responseData.ErrorCode)
+	}
+
+	for _, glueRecord := range responseData.ResponseData.GlueRecords {
+		if strings.EqualFold(glueRecord.Name, name) {
+			return glueRecord, nil
+		}
+	}
+	return GlueRecord{}, errors.ErrRecordNotFound
+}
+
+func setHeaders(request *http.Request) {
+	headers.SetUserAgent(request)
+	headers.SetContentType(request, "application/x-www-form-urlencoded")
+	headers.SetAccept(request, "application/json")
+}
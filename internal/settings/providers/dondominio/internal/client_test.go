@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qdm12/ddns-updater/internal/settings/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Client_UpdateDomainIP(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		name          string
+		responseBody  string
+		responseCode  int
+		ip            net.IP
+		expectedIP    net.IP
+		expectedError string
+		expectedErrIs error
+	}{
+		"success": {
+			name: "home",
+			responseBody: `{"success": true, "responseData": {"gluerecords": [
+				{"name": "home", "ipv4": "1.2.3.4"},
+				{"name": "vpn", "ipv4": "5.6.7.8"}
+			]}}`,
+			responseCode: http.StatusOK,
+			ip:           net.ParseIP("1.2.3.4"),
+			expectedIP:   net.ParseIP("1.2.3.4"),
+		},
+		"matching glue record is not first in the list": {
+			name: "home",
+			responseBody: `{"success": true, "responseData": {"gluerecords": [
+				{"name": "vpn", "ipv4": "5.6.7.8"},
+				{"name": "home", "ipv4": "1.2.3.4"}
+			]}}`,
+			responseCode: http.StatusOK,
+			ip:           net.ParseIP("1.2.3.4"),
+			expectedIP:   net.ParseIP("1.2.3.4"),
+		},
+		"bad http status": {
+			name:          "home",
+			responseBody:  `not json`,
+			responseCode:  http.StatusInternalServerError,
+			ip:            net.ParseIP("1.2.3.4"),
+			expectedError: "bad HTTP status",
+		},
+		"unsuccessful response": {
+			name:          "home",
+			responseBody:  `{"success": false, "errorCode": 310, "errorCodeMsg": "invalid auth"}`,
+			responseCode:  http.StatusOK,
+			ip:            net.ParseIP("1.2.3.4"),
+			expectedError: "invalid auth",
+		},
+		"no matching glue record": {
+			name: "home",
+			responseBody: `{"success": true, "responseData": {"gluerecords": [
+				{"name": "other", "ipv4": "1.2.3.4"}
+			]}}`,
+			responseCode:  http.StatusOK,
+			ip:            net.ParseIP("1.2.3.4"),
+			expectedErrIs: errors.ErrRecordNotFound,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(testCase.responseCode)
+					_, _ = w.Write([]byte(testCase.responseBody))
+				}))
+			defer server.Close()
+
+			client := NewClient(server.Client(), "user", "pass")
+			client.baseURL = server.URL
+
+			record, err := client.UpdateDomainIP(context.Background(), "example.com", testCase.name, testCase.ip)
+
+			if testCase.expectedErrIs != nil {
+				require.ErrorIs(t, err, testCase.expectedErrIs)
+				return
+			}
+			if testCase.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), testCase.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedIP.String(), record.IPv4)
+		})
+	}
+}
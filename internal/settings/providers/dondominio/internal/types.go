@@ -0,0 +1,21 @@
+package internal
+
+// updateDomainIPResponse is the body returned by the simple-API's
+// updatedomainip endpoint.
+type updateDomainIPResponse struct {
+	Success          bool         `json:"success"`
+	ErrorCode        int          `json:"errorCode"`
+	ErrorCodeMessage string       `json:"errorCodeMsg"`
+	ResponseData     responseData `json:"responseData"`
+}
+
+type responseData struct {
+	GlueRecords []GlueRecord `json:"gluerecords"`
+}
+
+// GlueRecord is a single A/AAAA glue record returned for the domain.
+type GlueRecord struct {
+	Name string `json:"name"`
+	IPv4 string `json:"ipv4"`
+	IPv6 string `json:"ipv6"`
+}
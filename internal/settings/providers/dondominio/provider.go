@@ -6,13 +6,11 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"net/url"
-	"strings"
 
 	"github.com/qdm12/ddns-updater/internal/models"
 	"github.com/qdm12/ddns-updater/internal/settings/constants"
 	"github.com/qdm12/ddns-updater/internal/settings/errors"
-	"github.com/qdm12/ddns-updater/internal/settings/headers"
+	"github.com/qdm12/ddns-updater/internal/settings/providers/dondominio/internal"
 	"github.com/qdm12/ddns-updater/internal/settings/utils"
 	"github.com/qdm12/ddns-updater/pkg/publicip/ipversion"
 )
@@ -24,6 +22,16 @@ type provider struct {
 	username  string
 	password  string
 	name      string
+
+	// newClient builds the API client to use for Update; it is
+	// internal.NewClient by default and overridden in tests.
+	newClient func(httpClient *http.Client, username, password string) apiClient
+}
+
+// apiClient is the subset of *internal.Client used by Update, extracted so
+// that tests can exercise apiName/Update without making real HTTP calls.
+type apiClient interface {
+	UpdateDomainIP(ctx context.Context, domain, name string, ip net.IP) (record internal.GlueRecord, err error)
 }
 
 func New(data json.RawMessage, domain, host string, ipVersion ipversion.IPVersion) (p *provider, err error) {
@@ -45,6 +53,7 @@ func New(data json.RawMessage, domain, host string, ipVersion ipversion.IPVersio
 		username:  extraSettings.Username,
 		password:  extraSettings.Password,
 		name:      extraSettings.Name,
+		newClient: newAPIClient,
 	}
 	if err := p.isValid(); err != nil {
 		return nil, err
@@ -60,12 +69,24 @@ func (p *provider) isValid() error {
 		return errors.ErrEmptyPassword
 	case len(p.name) == 0:
 		return errors.ErrEmptyName
-	case p.host != "@":
-		return errors.ErrHostOnlyAt
 	}
 	return nil
 }
 
+// apiName returns the value to send as the `name` parameter of the
+// simple-API: the configured account name for the root domain, or the
+// host label (optionally combined with the account name) for subdomains.
+func (p *provider) apiName() string {
+	switch {
+	case p.host == "@":
+		return p.name
+	case p.name == "@" || p.name == "":
+		return p.host
+	default:
+		return p.host + "." + p.name
+	}
+}
+
 func (p *provider) String() string {
 	return utils.ToString(p.domain, p.host, constants.DonDominio, p.ipVersion)
 }
@@ -99,70 +120,22 @@ func (p *provider) HTML() models.HTMLRow {
 	}
 }
 
-func (p *provider) setHeaders(request *http.Request) {
-	headers.SetUserAgent(request)
-	headers.SetContentType(request, "application/x-www-form-urlencoded")
-	headers.SetAccept(request, "application/json")
+// newAPIClient wraps internal.NewClient so its result satisfies apiClient.
+func newAPIClient(httpClient *http.Client, username, password string) apiClient {
+	return internal.NewClient(httpClient, username, password)
 }
 
-func (p *provider) Update(ctx context.Context, client *http.Client, ip net.IP) (newIP net.IP, err error) {
-	u := url.URL{
-		Scheme: "https",
-		Host:   "simple-api.dondominio.net",
-	}
-	values := url.Values{}
-	values.Set("apiuser", p.username)
-	values.Set("apipasswd", p.password)
-	values.Set("domain", p.domain)
-	values.Set("name", p.name)
-	isIPv4 := ip.To4() != nil
-	if isIPv4 {
-		values.Set("ipv4", ip.String())
-	} else {
-		values.Set("ipv6", ip.String())
-	}
-	buffer := strings.NewReader(values.Encode())
+func (p *provider) Update(ctx context.Context, httpClient *http.Client, ip net.IP) (newIP net.IP, err error) {
+	client := p.newClient(httpClient, p.username, p.password)
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), buffer)
+	record, err := client.UpdateDomainIP(ctx, p.domain, p.apiName(), ip)
 	if err != nil {
 		return nil, err
 	}
-	p.setHeaders(request)
 
-	response, err := client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%w: %d: %s",
-			errors.ErrBadHTTPStatus, response.StatusCode, utils.BodyToSingleLine(response.Body))
-	}
-
-	decoder := json.NewDecoder(response.Body)
-	var responseData struct {
-		Success          bool   `json:"success"`
-		ErrorCode        int    `json:"errorCode"`
-		ErrorCodeMessage string `json:"errorCodeMsg"`
-		ResponseData     struct {
-			GlueRecords []struct {
-				IPv4 string `json:"ipv4"`
-				IPv6 string `json:"ipv6"`
-			} `json:"gluerecords"`
-		} `json:"responseData"`
-	}
-	if err := decoder.Decode(&responseData); err != nil {
-		return nil, fmt.Errorf("%w: %s", errors.ErrUnmarshalResponse, err)
-	}
-
-	if !responseData.Success {
-		return nil, fmt.Errorf("%w: %s (error code %d)",
-			errors.ErrUnsuccessfulResponse, responseData.ErrorCodeMessage, responseData.ErrorCode)
-	}
-	ipString := responseData.ResponseData.GlueRecords[0].IPv4
-	if !isIPv4 {
-		ipString = responseData.ResponseData.GlueRecords[0].IPv6
+	ipString := record.IPv4
+	if ip.To4() == nil {
+		ipString = record.IPv6
 	}
 	newIP = net.ParseIP(ipString)
 	if newIP == nil {
@@ -0,0 +1,129 @@
+package dondominio
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/qdm12/ddns-updater/internal/settings/providers/dondominio/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_provider_apiName(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		host     string
+		name     string
+		expected string
+	}{
+		"root domain": {
+			host:     "@",
+			name:     "account-name",
+			expected: "account-name",
+		},
+		"subdomain with no account name": {
+			host:     "home",
+			name:     "",
+			expected: "home",
+		},
+		"subdomain with account name left at default": {
+			host:     "home",
+			name:     "@",
+			expected: "home",
+		},
+		"subdomain combined with account name": {
+			host:     "home",
+			name:     "account-name",
+			expected: "home.account-name",
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &provider{host: testCase.host, name: testCase.name}
+
+			assert.Equal(t, testCase.expected, p.apiName())
+		})
+	}
+}
+
+// fakeAPIClient implements apiClient for tests.
+type fakeAPIClient struct {
+	record internal.GlueRecord
+	err    error
+}
+
+func (f *fakeAPIClient) UpdateDomainIP(_ context.Context, _, _ string, _ net.IP) (internal.GlueRecord, error) {
+	return f.record, f.err
+}
+
+func Test_provider_Update(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		client      *fakeAPIClient
+		ip          net.IP
+		expectedIP  net.IP
+		expectedErr bool
+	}{
+		"ipv4 success": {
+			client:     &fakeAPIClient{record: internal.GlueRecord{IPv4: "1.2.3.4"}},
+			ip:         net.ParseIP("1.2.3.4"),
+			expectedIP: net.ParseIP("1.2.3.4"),
+		},
+		"ipv6 success": {
+			client:     &fakeAPIClient{record: internal.GlueRecord{IPv6: "::1"}},
+			ip:         net.ParseIP("::1"),
+			expectedIP: net.ParseIP("::1"),
+		},
+		"client error": {
+			client:      &fakeAPIClient{err: errors.New("boom")},
+			ip:          net.ParseIP("1.2.3.4"),
+			expectedErr: true,
+		},
+		"malformed ip in response": {
+			client:      &fakeAPIClient{record: internal.GlueRecord{IPv4: "not an ip"}},
+			ip:          net.ParseIP("1.2.3.4"),
+			expectedErr: true,
+		},
+		"mismatched ip in response": {
+			client:      &fakeAPIClient{record: internal.GlueRecord{IPv4: "5.6.7.8"}},
+			ip:          net.ParseIP("1.2.3.4"),
+			expectedErr: true,
+		},
+	}
+
+	for name, testCase := range testCases {
+		testCase := testCase
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			p := &provider{
+				domain:   "example.com",
+				host:     "home",
+				username: "user",
+				password: "pass",
+				name:     "account-name",
+				newClient: func(_ *http.Client, _, _ string) apiClient {
+					return testCase.client
+				},
+			}
+
+			newIP, err := p.Update(context.Background(), nil, testCase.ip)
+
+			if testCase.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedIP, newIP)
+		})
+	}
+}